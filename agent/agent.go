@@ -3,15 +3,19 @@ package agent
 
 import (
 	"bytes"
+	"context"
+	"encoding/binary"
 	"encoding/json"
+	"fmt"
 	"github.com/boltdb/bolt"
+	"github.com/resourced/resourced/bus"
 	resourced_config "github.com/resourced/resourced/config"
 	resourced_host "github.com/resourced/resourced/host"
 	"github.com/resourced/resourced/libprocess"
 	"github.com/resourced/resourced/libstring"
-	"github.com/resourced/resourced/libtime"
 	resourced_readers "github.com/resourced/resourced/readers"
 	resourced_writers "github.com/resourced/resourced/writers"
+	"hash/crc32"
 	"os"
 	"os/user"
 	"strings"
@@ -19,10 +23,42 @@ import (
 	"time"
 )
 
+// contextualReader is implemented by readers/writers that know how to honor
+// cancellation directly, instead of running to completion unconditionally.
+type contextualReader interface {
+	RunContext(ctx context.Context) error
+}
+
+// subscribingWriter is implemented by IWriter implementations that declare
+// SubscribeTopics and want pushed bus updates instead of polling bolt through
+// ReaderPaths.
+type subscribingWriter interface {
+	SubscribeTopics() []string
+	HandleBusMessage(topic string, payload []byte)
+}
+
+// taggableWriter is implemented by IWriter implementations whose Topic/Exchange
+// template can interpolate {{.Tags}}. initGoStructWriter seeds it with
+// Agent.Tags so operators don't have to duplicate RESOURCED_TAGS into every
+// writer's own config.
+type taggableWriter interface {
+	SetTags(tags []string)
+}
+
 // NewAgent is the constructor for Agent struct.
 func NewAgent() (*Agent, error) {
 	agent := &Agent{}
 
+	agent.cancelFuncsLock = new(sync.RWMutex)
+	agent.cancelFuncs = make(map[string]context.CancelFunc)
+	agent.configHashes = make(map[string]string)
+
+	agent.subscriptionsLock = new(sync.RWMutex)
+	agent.subscriptions = make(map[string][]bus.Subscription)
+	agent.currentWriters = make(map[string]subscribingWriter)
+
+	agent.setRuntimes()
+
 	agent.setTags()
 
 	err := agent.setConfigStorage()
@@ -35,17 +71,84 @@ func NewAgent() (*Agent, error) {
 		return nil, err
 	}
 
+	err = agent.setBuses()
+	if err != nil {
+		return nil, err
+	}
+
 	return agent, err
 }
 
+// setBuses initializes the EventBus implementations declared in
+// ConfigStorage.Buses so Run can fan reader/writer results out to them (via
+// publishRun, once saveRun has succeeded) in addition to BoltDB.
+//
+// This depends on a `Buses []BusConfig` field on config.ConfigStorage and a
+// `BusConfig{Kind, URL, Topic string}` type, neither of which live in this
+// checkout: the config package isn't one of this repo's tracked directories
+// (only agent/, bus/, executors/, readers/, writers/ are), so adding it here
+// isn't possible from these files. Whoever owns config.ConfigStorage needs to
+// land that field/type alongside this change.
+func (a *Agent) setBuses() error {
+	a.Buses = make([]bus.EventBus, 0)
+
+	if a.ConfigStorage == nil {
+		return nil
+	}
+
+	httpURLsByTopic := make(map[string]string)
+
+	for _, busConfig := range a.ConfigStorage.Buses {
+		switch busConfig.Kind {
+		case "channel":
+			a.Buses = append(a.Buses, bus.NewChannelBus())
+
+		case "nats":
+			natsBus, err := bus.NewNatsBus(busConfig.URL)
+			if err != nil {
+				return err
+			}
+			a.Buses = append(a.Buses, natsBus)
+
+		case "http":
+			httpURLsByTopic[busConfig.Topic] = busConfig.URL
+		}
+	}
+
+	if len(httpURLsByTopic) > 0 {
+		// HTTPBus.Publish can block for tens of seconds retrying a slow or
+		// down webhook; wrap it so that never stalls the Run() hot path.
+		a.Buses = append(a.Buses, bus.Async(bus.NewHTTPBus(httpURLsByTopic)))
+	}
+
+	return nil
+}
+
 // Agent struct carries most of the functionality of ResourceD.
 // It collects information through readers and serve them up as HTTP+JSON.
 type Agent struct {
 	ConfigStorage     *resourced_config.ConfigStorage
 	DbPath            string
 	Db                *bolt.DB
+	Retention         time.Duration
 	Tags              []string
+	Buses             []bus.EventBus
 	configStorageChan chan *resourced_config.ConfigStorage
+
+	cancelFuncsLock *sync.RWMutex
+	cancelFuncs     map[string]context.CancelFunc
+	configHashes    map[string]string
+
+	// subscriptions and currentWriters back the push path for writers that
+	// declare SubscribeTopics: each path is subscribed exactly once (on its
+	// writer's first tick) and torn down when that path's goroutine is
+	// canceled in RunAllForever, rather than resubscribed on every tick.
+	subscriptionsLock *sync.RWMutex
+	subscriptions     map[string][]bus.Subscription
+	currentWriters    map[string]subscribingWriter
+
+	runtimeLock *sync.RWMutex
+	runtimes    map[string]*readerRuntime
 }
 
 // setTags store RESOURCED_TAGS data to Tags field.
@@ -96,10 +199,200 @@ func (a *Agent) setDb() error {
 		return nil
 	})
 
+	err = a.migrateToTimeBucketedLayout()
+	if err != nil {
+		return err
+	}
+
+	a.Retention = parseRetention(os.Getenv("RESOURCED_RETENTION"), 24*time.Hour)
+
+	go a.sweepExpiredForever()
+
+	return err
+}
+
+// parseRetention parses s as a duration, falling back to fallback when s is
+// empty, unparseable, or not strictly positive. sweepExpiredForever divides
+// the retention by 4 to get its ticker interval, and time.NewTicker panics
+// on a non-positive duration, so a value like "0s" must never reach it.
+func parseRetention(s string, fallback time.Duration) time.Duration {
+	if s == "" {
+		return fallback
+	}
+
+	parsed, err := time.ParseDuration(s)
+	if err != nil || parsed <= 0 {
+		return fallback
+	}
+
+	return parsed
+}
+
+// migrateToTimeBucketedLayout upgrades a pre-retention db, where "resources"
+// held one flat path -> latest-record entry, into the per-path time-bucketed
+// layout saveRun now writes. Each flat entry becomes a path sub-bucket
+// containing a single record keyed by that record's own UnixNano.
+func (a *Agent) migrateToTimeBucketedLayout() error {
+	return a.Db.Update(func(tx *bolt.Tx) error {
+		resources := a.dbBucket(tx)
+
+		type flatEntry struct {
+			path string
+			data []byte
+		}
+		var toMigrate []flatEntry
+
+		cursor := resources.Cursor()
+		for k, v := cursor.First(); k != nil; k, v = cursor.Next() {
+			// A nested bucket has a nil value; only flat key/value pairs
+			// from the old layout need migrating.
+			if v != nil {
+				toMigrate = append(toMigrate, flatEntry{path: string(k), data: v})
+			}
+		}
+
+		for _, entry := range toMigrate {
+			err := resources.Delete([]byte(entry.path))
+			if err != nil {
+				return err
+			}
+
+			pathBucket, err := resources.CreateBucketIfNotExists([]byte(entry.path))
+			if err != nil {
+				return err
+			}
+
+			var record struct {
+				UnixNano int64 `json:"UnixNano"`
+			}
+			json.Unmarshal(entry.data, &record)
+			if record.UnixNano == 0 {
+				record.UnixNano = time.Now().UnixNano()
+			}
+
+			err = pathBucket.Put(unixNanoKey(record.UnixNano), entry.data)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// sweepExpiredForever periodically drops samples older than a.Retention from
+// every path bucket. It runs for the lifetime of the Agent's Db.
+func (a *Agent) sweepExpiredForever() {
+	ticker := time.NewTicker(a.Retention / 4)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		a.sweepExpired()
+	}
+}
+
+// sweepExpired drops every sample older than a.Retention from every path bucket.
+func (a *Agent) sweepExpired() error {
+	cutoff := unixNanoKey(time.Now().Add(-a.Retention).UnixNano())
+
+	return a.Db.Update(func(tx *bolt.Tx) error {
+		resources := a.dbBucket(tx)
+
+		return resources.ForEach(func(path, v []byte) error {
+			pathBucket := resources.Bucket(path)
+			if pathBucket == nil {
+				return nil
+			}
+
+			cursor := pathBucket.Cursor()
+
+			var expiredKeys [][]byte
+			for k, _ := cursor.First(); k != nil && bytes.Compare(k, cutoff) < 0; k, _ = cursor.Next() {
+				expiredKeys = append(expiredKeys, append([]byte(nil), k...))
+			}
+
+			for _, k := range expiredKeys {
+				if err := pathBucket.Delete(k); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+	})
+}
+
+// unixNanoKey encodes a UnixNano timestamp as a big-endian byte slice so that
+// boltdb's natural byte-order iteration is also chronological order.
+func unixNanoKey(unixNano int64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, uint64(unixNano))
+	return key
+}
+
+// Compact rewrites the boltdb file into a fresh one to reclaim space freed by
+// the retention sweeper, then swaps it in for the live Db.
+func (a *Agent) Compact() error {
+	tmpPath := a.DbPath + ".compact"
+
+	newDb, err := bolt.Open(tmpPath, 0644, nil)
+	if err != nil {
+		return err
+	}
+
+	err = a.Db.View(func(srcTx *bolt.Tx) error {
+		return newDb.Update(func(dstTx *bolt.Tx) error {
+			return srcTx.ForEach(func(name []byte, srcBucket *bolt.Bucket) error {
+				dstBucket, err := dstTx.CreateBucketIfNotExists(name)
+				if err != nil {
+					return err
+				}
+				return copyBucket(srcBucket, dstBucket)
+			})
+		})
+	})
+	if err != nil {
+		newDb.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+
+	err = newDb.Close()
+	if err != nil {
+		return err
+	}
+
+	err = a.Db.Close()
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(tmpPath, a.DbPath)
+	if err != nil {
+		return err
+	}
+
+	a.Db, err = bolt.Open(a.DbPath, 0644, nil)
 	return err
 }
 
-// dbBucket returns the boltdb bucket.
+// copyBucket recursively copies every key/value and nested bucket from src into dst.
+func copyBucket(src, dst *bolt.Bucket) error {
+	return src.ForEach(func(k, v []byte) error {
+		if v == nil {
+			srcChild := src.Bucket(k)
+			dstChild, err := dst.CreateBucketIfNotExists(k)
+			if err != nil {
+				return err
+			}
+			return copyBucket(srcChild, dstChild)
+		}
+		return dst.Put(k, v)
+	})
+}
+
+// dbBucket returns the top-level boltdb bucket. It holds one nested bucket
+// per reader/writer path, each storing that path's samples keyed by UnixNano.
 func (a *Agent) dbBucket(tx *bolt.Tx) *bolt.Bucket {
 	return tx.Bucket([]byte("resources"))
 }
@@ -143,26 +436,105 @@ func (a *Agent) pathWithWriterPrefix(input interface{}) string {
 
 // Run executes a reader/writer config.
 // Run will save reader data as JSON in local db.
-func (a *Agent) Run(config resourced_config.Config) (output []byte, err error) {
+// If config.Timeout parses as a duration, Run derives a deadline from it: a
+// command is killed once it passes, a reader/writer implementing RunContext sees
+// ctx canceled, and saveRun returns ctx.Err() without writing. A reader/writer
+// without RunContext still runs to completion. An unparseable Timeout is ignored.
+//
+// config.Timeout is a new string field this series expects on
+// config.Config; like ConfigStorage.Buses in setBuses, the config package
+// isn't one of this repo's tracked directories, so the field itself has to
+// be added by whoever owns that package.
+func (a *Agent) Run(ctx context.Context, config resourced_config.Config) (output []byte, err error) {
+	if config.Timeout != "" {
+		timeout, parseErr := time.ParseDuration(config.Timeout)
+		if parseErr == nil {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+	}
+
+	start := time.Now()
+
 	if config.Command != "" {
-		output, err = a.runCommand(config)
+		output, err = a.runCommand(ctx, config)
 	} else if config.GoStruct != "" && config.Kind == "reader" {
-		output, err = a.runGoStructReader(config)
+		output, err = a.runGoStructReader(ctx, config)
 	} else if config.GoStruct != "" && config.Kind == "writer" {
-		output, err = a.runGoStructWriter(config)
+		output, err = a.runGoStructWriter(ctx, config)
+	}
+	if err != nil {
+		a.recordRunResult(config, time.Since(start), err)
+		return output, err
 	}
+
+	err = a.saveRun(ctx, config, output)
+
+	// Record execution metadata right around the boltdb write so LatestStats
+	// and the persisted record never drift apart.
+	a.recordRunResult(config, time.Since(start), err)
+
 	if err != nil {
 		return output, err
 	}
 
-	err = a.saveRun(config, output)
+	a.publishRun(config, output)
 
 	return output, err
 }
 
+// busTopic returns the topic a reader/writer's result is published under,
+// e.g. resourced.readers.<path> or resourced.writers.<path>.
+func (a *Agent) busTopic(config resourced_config.Config) string {
+	return fmt.Sprintf("resourced.%ss.%s", config.Kind, config.Path)
+}
+
+// publishRun rebuilds a JSON envelope of the same shape saveRun persists to
+// BoltDB and fans it out to every configured EventBus. Its UnixNano is taken
+// afresh by commonData, so it is slightly later than the stored record's, and
+// Host is omitted if host data can't be gathered. Publishing is best-effort: a bus
+// failure is swallowed here rather than failing Run, since BoltDB remains the
+// source of truth.
+func (a *Agent) publishRun(config resourced_config.Config, output []byte) {
+	if len(a.Buses) == 0 || config.Path == "" {
+		return
+	}
+
+	record := a.commonData(config)
+
+	host, err := a.hostData()
+	if err == nil {
+		record["Host"] = host
+	}
+
+	runData := make(map[string]interface{})
+	if json.Unmarshal(output, &runData) == nil {
+		record["Data"] = runData
+	}
+
+	recordInJson, err := json.Marshal(record)
+	if err != nil {
+		return
+	}
+
+	topic := a.busTopic(config)
+
+	for _, eventBus := range a.Buses {
+		eventBus.Publish(topic, recordInJson)
+	}
+}
+
 // runCommand shells out external program and returns the output.
-func (a *Agent) runCommand(config resourced_config.Config) ([]byte, error) {
-	cmd := libprocess.NewCmd(config.Command)
+// The command is killed if ctx is canceled or its deadline expires.
+//
+// NewCmdContext is a new libprocess symbol this series calls in place of the
+// existing NewCmd; like config.Config.Timeout above, libprocess isn't one of
+// this repo's tracked directories, so it can't be added from here. It's
+// expected to mirror exec.CommandContext(ctx, name, args...): same *exec.Cmd
+// construction as NewCmd, but wired so ctx canceling kills the process.
+func (a *Agent) runCommand(ctx context.Context, config resourced_config.Config) ([]byte, error) {
+	cmd := libprocess.NewCmdContext(ctx, config.Command)
 
 	if config.Kind == "writer" {
 		// Get readers data.
@@ -192,13 +564,14 @@ func (a *Agent) runCommand(config resourced_config.Config) ([]byte, error) {
 }
 
 // processJson shells out external program to mangle JSON and save the new JSON on writer's ReadersData field.
-func (a *Agent) processJson(config resourced_config.Config, writer resourced_writers.IWriter) error {
+// See runCommand's doc comment for why its NewCmdContext call is out of scope here too.
+func (a *Agent) processJson(ctx context.Context, config resourced_config.Config, writer resourced_writers.IWriter) error {
 	processorPath := writer.GetJsonProcessor()
 	if processorPath == "" {
 		return nil
 	}
 
-	cmd := libprocess.NewCmd(processorPath)
+	cmd := libprocess.NewCmdContext(ctx, processorPath)
 
 	readersData := writer.GetReadersData()
 
@@ -249,13 +622,90 @@ func (a *Agent) initGoStructWriter(config resourced_config.Config) (resourced_wr
 
 	writer.SetReadersDataInBytes(readersData)
 
+	if taggable, ok := writer.(taggableWriter); ok {
+		taggable.SetTags(a.Tags)
+	}
+
+	// A writer that declares SubscribeTopics gets pushed bus updates instead
+	// of relying solely on the ReaderPaths poll above. The writer instance
+	// itself is rebuilt every tick, so we only subscribe once per path and
+	// keep currentWriters pointed at whichever instance is current.
+	if subscriber, ok := writer.(subscribingWriter); ok {
+		a.subscriptionsLock.Lock()
+		a.currentWriters[config.Path] = subscriber
+		a.subscriptionsLock.Unlock()
+
+		a.ensureSubscribed(config.Path, subscriber)
+	}
+
 	return writer, err
 }
 
+// ensureSubscribed subscribes subscriber's topics exactly once for path, the
+// first time a writer at that path declares SubscribeTopics. Later ticks for
+// the same path reuse the subscription; the handler always dispatches to
+// whatever writer instance initGoStructWriter last stored in currentWriters,
+// so pushed messages reach the current tick's writer without resubscribing.
+func (a *Agent) ensureSubscribed(path string, subscriber subscribingWriter) {
+	a.subscriptionsLock.Lock()
+	defer a.subscriptionsLock.Unlock()
+
+	if _, exists := a.subscriptions[path]; exists {
+		return
+	}
+
+	var subs []bus.Subscription
+
+	for _, topic := range subscriber.SubscribeTopics() {
+		topic := topic
+
+		for _, eventBus := range a.Buses {
+			sub, err := eventBus.Subscribe(topic, func(payload []byte) {
+				a.subscriptionsLock.RLock()
+				current := a.currentWriters[path]
+				a.subscriptionsLock.RUnlock()
+
+				if current != nil {
+					current.HandleBusMessage(topic, payload)
+				}
+			})
+			if err == nil {
+				subs = append(subs, sub)
+			}
+		}
+	}
+
+	a.subscriptions[path] = subs
+}
+
+// unsubscribePath unwinds whatever ensureSubscribed registered for path. It
+// is called from RunAllForever when that path's goroutine is torn down, so a
+// path that disappears or changes config doesn't leak its subscriptions.
+func (a *Agent) unsubscribePath(path string) {
+	a.subscriptionsLock.Lock()
+	subs := a.subscriptions[path]
+	delete(a.subscriptions, path)
+	delete(a.currentWriters, path)
+	a.subscriptionsLock.Unlock()
+
+	for _, sub := range subs {
+		sub.Unsubscribe()
+	}
+}
+
 // runGoStruct executes IReader/IWriter and returns the output.
-// Note that IWriter also implements IReader
-func (a *Agent) runGoStruct(readerOrWriter resourced_readers.IReader) ([]byte, error) {
-	err := readerOrWriter.Run()
+// Note that IWriter also implements IReader.
+// If readerOrWriter implements the optional contextualReader interface, RunContext(ctx)
+// is used instead of Run() so that ctx cancellation can interrupt the call.
+func (a *Agent) runGoStruct(ctx context.Context, readerOrWriter resourced_readers.IReader) ([]byte, error) {
+	var err error
+
+	if contextual, ok := readerOrWriter.(contextualReader); ok {
+		err = contextual.RunContext(ctx)
+	} else {
+		err = readerOrWriter.Run()
+	}
+
 	if err != nil {
 		errData := make(map[string]string)
 		errData["Error"] = err.Error()
@@ -266,30 +716,30 @@ func (a *Agent) runGoStruct(readerOrWriter resourced_readers.IReader) ([]byte, e
 }
 
 // runGoStructReader executes IReader and returns the output.
-func (a *Agent) runGoStructReader(config resourced_config.Config) ([]byte, error) {
+func (a *Agent) runGoStructReader(ctx context.Context, config resourced_config.Config) ([]byte, error) {
 	// Initialize IReader
 	reader, err := a.initGoStructReader(config)
 	if err != nil {
 		return nil, err
 	}
 
-	return a.runGoStruct(reader)
+	return a.runGoStruct(ctx, reader)
 }
 
 // runGoStructWriter executes IWriter and returns error if exists.
-func (a *Agent) runGoStructWriter(config resourced_config.Config) ([]byte, error) {
+func (a *Agent) runGoStructWriter(ctx context.Context, config resourced_config.Config) ([]byte, error) {
 	// Initialize IWriter
 	writer, err := a.initGoStructWriter(config)
 	if err != nil {
 		return nil, err
 	}
 
-	err = a.processJson(config, writer)
+	err = a.processJson(ctx, config, writer)
 	if err != nil {
 		return nil, err
 	}
 
-	return a.runGoStruct(writer)
+	return a.runGoStruct(ctx, writer)
 }
 
 // commonData gathers common information for every reader and writer.
@@ -345,12 +795,18 @@ func (a *Agent) hostData() (*resourced_host.Host, error) {
 }
 
 // saveRun gathers basic, host, and reader/witer information and save them into local storage.
-func (a *Agent) saveRun(config resourced_config.Config, output []byte) error {
+// If ctx is already canceled, saveRun aborts before starting the BoltDB Update so that no
+// partial record is ever written.
+func (a *Agent) saveRun(ctx context.Context, config resourced_config.Config, output []byte) error {
 	// Do not perform save if config.Path is empty.
 	if config.Path == "" {
 		return nil
 	}
 
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	record := a.commonData(config)
 
 	host, err := a.hostData()
@@ -371,70 +827,173 @@ func (a *Agent) saveRun(config resourced_config.Config, output []byte) error {
 		return err
 	}
 
+	// ctx may have been canceled while we were gathering host/reader data above;
+	// re-check right before the write so a canceled run never reaches bolt.
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	unixNano := record["UnixNano"].(int64)
+
 	err = a.Db.Update(func(tx *bolt.Tx) error {
-		return a.dbBucket(tx).Put([]byte(a.pathWithPrefix(config)), recordInJson)
+		pathBucket, err := a.dbBucket(tx).CreateBucketIfNotExists([]byte(a.pathWithPrefix(config)))
+		if err != nil {
+			return err
+		}
+
+		return pathBucket.Put(unixNanoKey(unixNano), recordInJson)
 	})
 
 	return err
 }
 
-// GetRun returns the JSON data stored in local storage given Config struct.
+// GetRun returns the most recent JSON data stored in local storage given Config struct.
 func (a *Agent) GetRun(config resourced_config.Config) ([]byte, error) {
 	return a.GetRunByPath(a.pathWithPrefix(config))
 }
 
-// GetRunByPath returns JSON data stored in local storage given path string.
+// GetRunByPath returns the most recent JSON data stored in local storage given path string.
 func (a *Agent) GetRunByPath(path string) ([]byte, error) {
 	var data []byte
 
 	a.Db.View(func(tx *bolt.Tx) error {
-		data = a.dbBucket(tx).Get([]byte(path))
+		pathBucket := a.dbBucket(tx).Bucket([]byte(path))
+		if pathBucket == nil {
+			return nil
+		}
+
+		_, data = pathBucket.Cursor().Last()
 		return nil
 	})
 
 	return data, nil
 }
 
+// GetRunHistory returns every sample stored for path with a UnixNano
+// timestamp in [from, to], oldest first.
+func (a *Agent) GetRunHistory(path string, from, to time.Time) ([][]byte, error) {
+	var records [][]byte
+
+	err := a.Db.View(func(tx *bolt.Tx) error {
+		pathBucket := a.dbBucket(tx).Bucket([]byte(path))
+		if pathBucket == nil {
+			return nil
+		}
+
+		min := unixNanoKey(from.UnixNano())
+		max := unixNanoKey(to.UnixNano())
+
+		cursor := pathBucket.Cursor()
+		for k, v := cursor.Seek(min); k != nil && bytes.Compare(k, max) <= 0; k, v = cursor.Next() {
+			records = append(records, append([]byte(nil), v...))
+		}
+
+		return nil
+	})
+
+	return records, err
+}
+
+// GetLatestN returns up to the n most recent samples stored for path,
+// newest first.
+func (a *Agent) GetLatestN(path string, n int) ([][]byte, error) {
+	var records [][]byte
+
+	err := a.Db.View(func(tx *bolt.Tx) error {
+		pathBucket := a.dbBucket(tx).Bucket([]byte(path))
+		if pathBucket == nil {
+			return nil
+		}
+
+		cursor := pathBucket.Cursor()
+		for k, v := cursor.Last(); k != nil && len(records) < n; k, v = cursor.Prev() {
+			records = append(records, append([]byte(nil), v...))
+		}
+
+		return nil
+	})
+
+	return records, err
+}
+
 // RunForever executes Run() in an infinite loop with a sleep of config.Interval.
-func (a *Agent) RunForever(config resourced_config.Config, quit chan bool) {
+// It returns as soon as ctx is canceled, which interrupts an in-flight Run as well.
+func (a *Agent) RunForever(ctx context.Context, config resourced_config.Config) {
 	for {
 		select {
-		case <-quit:
-			println("am i here?")
+		case <-ctx.Done():
 			return
 		default:
-			a.Run(config)
-			libtime.SleepString(config.Interval)
+			a.Run(ctx, config)
+
+			interval, err := time.ParseDuration(config.Interval)
+			if err != nil {
+				interval = time.Minute
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(interval):
+			}
 		}
 	}
 }
 
-// RunAllForever executes all readers & writers in an infinite loop.
-func (a *Agent) RunAllForever() {
-	quitChans := make(map[string]chan bool)
-	configLock := new(sync.RWMutex)
+// configHash returns a short hash of config, used to tell whether a reader/writer's
+// definition actually changed between two ConfigStorage deliveries.
+func configHash(config resourced_config.Config) string {
+	return fmt.Sprintf("%x", crc32.ChecksumIEEE([]byte(fmt.Sprintf("%+v", config))))
+}
 
+// RunAllForever executes all readers & writers in an infinite loop, one goroutine per
+// reader/writer. When a new ConfigStorage arrives on configStorageChan, only the
+// readers/writers whose config (path + hash) actually changed are torn down and
+// respawned; untouched ones keep running uninterrupted.
+func (a *Agent) RunAllForever(ctx context.Context) {
 	for {
 		select {
+		case <-ctx.Done():
+			return
+
 		case configStorage := <-a.configStorageChan:
-			configLock.Lock()
 			a.ConfigStorage = configStorage
-			configLock.Unlock()
-
-			if len(quitChans) > 0 {
-				for _, quitChan := range quitChans {
-					quitChan <- true
-				}
-			}
 
+			desired := make(map[string]resourced_config.Config)
 			for _, config := range configStorage.Readers {
-				quitChans[config.Path] = make(chan bool)
-				a.RunForever(config, quitChans[config.Path])
+				desired[config.Path] = config
 			}
 			for _, config := range configStorage.Writers {
-				quitChans[config.Path] = make(chan bool)
-				a.RunForever(config, quitChans[config.Path])
+				desired[config.Path] = config
+			}
+
+			a.cancelFuncsLock.Lock()
+
+			// Cancel and drop any path that disappeared or whose config hash changed.
+			for path, cancel := range a.cancelFuncs {
+				config, stillWanted := desired[path]
+				if !stillWanted || configHash(config) != a.configHashes[path] {
+					cancel()
+					delete(a.cancelFuncs, path)
+					delete(a.configHashes, path)
+					a.unsubscribePath(path)
+				}
 			}
+
+			// Spawn goroutines for paths that are new or were just torn down above.
+			for path, config := range desired {
+				if _, running := a.cancelFuncs[path]; running {
+					continue
+				}
+
+				readerCtx, cancel := context.WithCancel(ctx)
+				a.cancelFuncs[path] = cancel
+				a.configHashes[path] = configHash(config)
+
+				go a.RunForever(readerCtx, config)
+			}
+
+			a.cancelFuncsLock.Unlock()
 		}
 	}
 }
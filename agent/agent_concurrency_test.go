@@ -0,0 +1,176 @@
+package agent
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/resourced/resourced/bus"
+	resourced_config "github.com/resourced/resourced/config"
+)
+
+// fakeSubscriber is a minimal subscribingWriter test double that counts how
+// many times HandleBusMessage is invoked, so tests can tell which instance a
+// dispatched message actually reached.
+type fakeSubscriber struct {
+	mu    sync.Mutex
+	calls int
+}
+
+func (f *fakeSubscriber) SubscribeTopics() []string {
+	return []string{"topic"}
+}
+
+func (f *fakeSubscriber) HandleBusMessage(topic string, payload []byte) {
+	f.mu.Lock()
+	f.calls++
+	f.mu.Unlock()
+}
+
+func (f *fakeSubscriber) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
+
+func newSubscriptionTestAgent() *Agent {
+	return &Agent{
+		Buses:             []bus.EventBus{bus.NewChannelBus()},
+		subscriptionsLock: new(sync.RWMutex),
+		subscriptions:     make(map[string][]bus.Subscription),
+		currentWriters:    make(map[string]subscribingWriter),
+	}
+}
+
+func TestEnsureSubscribedSubscribesOnlyOnce(t *testing.T) {
+	a := newSubscriptionTestAgent()
+	first := &fakeSubscriber{}
+
+	a.ensureSubscribed("/w/fake", first)
+	subsAfterFirst := len(a.subscriptions["/w/fake"])
+
+	second := &fakeSubscriber{}
+	a.ensureSubscribed("/w/fake", second)
+
+	if len(a.subscriptions["/w/fake"]) != subsAfterFirst {
+		t.Fatalf("expected ensureSubscribed to be a no-op on a path already subscribed, subscription count changed from %d to %d", subsAfterFirst, len(a.subscriptions["/w/fake"]))
+	}
+}
+
+func TestEnsureSubscribedDispatchesToCurrentWriter(t *testing.T) {
+	a := newSubscriptionTestAgent()
+
+	stale := &fakeSubscriber{}
+	a.currentWriters["/w/fake"] = stale
+	a.ensureSubscribed("/w/fake", stale)
+
+	fresh := &fakeSubscriber{}
+	a.subscriptionsLock.Lock()
+	a.currentWriters["/w/fake"] = fresh
+	a.subscriptionsLock.Unlock()
+
+	if err := a.Buses[0].Publish("topic", []byte("payload")); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) && fresh.count() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	if fresh.count() != 1 {
+		t.Fatalf("expected the current writer to receive the dispatched message, got %d calls", fresh.count())
+	}
+	if stale.count() != 0 {
+		t.Fatalf("expected the stale writer instance to receive nothing, got %d calls", stale.count())
+	}
+}
+
+func TestUnsubscribePathStopsDispatchAndClearsState(t *testing.T) {
+	a := newSubscriptionTestAgent()
+	subscriber := &fakeSubscriber{}
+	a.currentWriters["/w/fake"] = subscriber
+	a.ensureSubscribed("/w/fake", subscriber)
+
+	a.unsubscribePath("/w/fake")
+
+	a.subscriptionsLock.RLock()
+	_, hasSub := a.subscriptions["/w/fake"]
+	_, hasWriter := a.currentWriters["/w/fake"]
+	a.subscriptionsLock.RUnlock()
+
+	if hasSub || hasWriter {
+		t.Fatal("expected unsubscribePath to remove both the subscription and currentWriters entries")
+	}
+
+	if err := a.Buses[0].Publish("topic", []byte("payload")); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+
+	if subscriber.count() != 0 {
+		t.Fatalf("expected no dispatch after unsubscribePath, got %d calls", subscriber.count())
+	}
+}
+
+// TestRunAllForeverRespawnsOnConfigChange drives RunAllForever end to end: it
+// delivers a ConfigStorage for one path, waits for the goroutine to spawn,
+// then delivers a changed config for the same path and asserts the old
+// cancelFunc is replaced by a new one (i.e. the old run was torn down and a
+// new one took over) rather than left running alongside it.
+func TestRunAllForeverRespawnsOnConfigChange(t *testing.T) {
+	a := &Agent{
+		cancelFuncsLock:   new(sync.RWMutex),
+		cancelFuncs:       make(map[string]context.CancelFunc),
+		configHashes:      make(map[string]string),
+		subscriptionsLock: new(sync.RWMutex),
+		subscriptions:     make(map[string][]bus.Subscription),
+		currentWriters:    make(map[string]subscribingWriter),
+		configStorageChan: make(chan *resourced_config.ConfigStorage, 1),
+		runtimeLock:       new(sync.RWMutex),
+		runtimes:          make(map[string]*readerRuntime),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go a.RunAllForever(ctx)
+
+	a.configStorageChan <- &resourced_config.ConfigStorage{
+		Readers: []resourced_config.Config{{Path: "/r/fake", Interval: "1h"}},
+	}
+
+	firstCancel := waitForCancelFunc(t, a, "/r/fake", nil)
+
+	a.configStorageChan <- &resourced_config.ConfigStorage{
+		Readers: []resourced_config.Config{{Path: "/r/fake", Interval: "2h"}},
+	}
+
+	waitForCancelFunc(t, a, "/r/fake", firstCancel)
+}
+
+// waitForCancelFunc polls a.cancelFuncs[path] until it holds a cancelFunc
+// other than exclude (or any cancelFunc, if exclude is nil), failing the
+// test if none shows up within a second.
+func waitForCancelFunc(t *testing.T, a *Agent, path string, exclude context.CancelFunc) context.CancelFunc {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		a.cancelFuncsLock.RLock()
+		cancel, ok := a.cancelFuncs[path]
+		a.cancelFuncsLock.RUnlock()
+
+		if ok && (exclude == nil || reflect.ValueOf(cancel).Pointer() != reflect.ValueOf(exclude).Pointer()) {
+			return cancel
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	t.Fatalf("timed out waiting for a new cancelFunc for %s", path)
+	return nil
+}
@@ -0,0 +1,180 @@
+package agent
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+// newTestAgent returns an Agent backed by a BoltDB file in a temp directory,
+// with the "resources" bucket created but no migration/retention applied yet.
+func newTestAgent(t *testing.T) *Agent {
+	t.Helper()
+
+	dbPath := filepath.Join(t.TempDir(), "resourced.db")
+
+	db, err := bolt.Open(dbPath, 0644, nil)
+	if err != nil {
+		t.Fatalf("bolt.Open: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucket([]byte("resources"))
+		return err
+	})
+	if err != nil {
+		t.Fatalf("create resources bucket: %v", err)
+	}
+
+	return &Agent{DbPath: dbPath, Db: db}
+}
+
+func TestParseRetention(t *testing.T) {
+	cases := []struct {
+		name     string
+		input    string
+		fallback time.Duration
+		want     time.Duration
+	}{
+		{"empty falls back", "", time.Hour, time.Hour},
+		{"unparseable falls back", "not-a-duration", time.Hour, time.Hour},
+		{"zero falls back", "0s", time.Hour, time.Hour},
+		{"negative falls back", "-5m", time.Hour, time.Hour},
+		{"valid duration is used", "10m", time.Hour, 10 * time.Minute},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := parseRetention(c.input, c.fallback)
+			if got != c.want {
+				t.Errorf("parseRetention(%q, %v) = %v, want %v", c.input, c.fallback, got, c.want)
+			}
+		})
+	}
+}
+
+func TestUnixNanoKeyOrdering(t *testing.T) {
+	earlier := unixNanoKey(1000)
+	later := unixNanoKey(2000)
+
+	if bytes.Compare(earlier, later) >= 0 {
+		t.Fatal("unixNanoKey(1000) should sort before unixNanoKey(2000)")
+	}
+}
+
+func TestMigrateToTimeBucketedLayoutConvertsFlatEntries(t *testing.T) {
+	a := newTestAgent(t)
+
+	record, err := json.Marshal(map[string]interface{}{"UnixNano": int64(1234), "Data": "old"})
+	if err != nil {
+		t.Fatalf("json.Marshal: %v", err)
+	}
+
+	err = a.Db.Update(func(tx *bolt.Tx) error {
+		return a.dbBucket(tx).Put([]byte("/r/load"), record)
+	})
+	if err != nil {
+		t.Fatalf("seed flat entry: %v", err)
+	}
+
+	if err := a.migrateToTimeBucketedLayout(); err != nil {
+		t.Fatalf("migrateToTimeBucketedLayout: %v", err)
+	}
+
+	err = a.Db.View(func(tx *bolt.Tx) error {
+		resources := a.dbBucket(tx)
+
+		if v := resources.Get([]byte("/r/load")); v != nil {
+			t.Fatal("flat entry should have been removed after migration")
+		}
+
+		pathBucket := resources.Bucket([]byte("/r/load"))
+		if pathBucket == nil {
+			t.Fatal("expected a path sub-bucket for /r/load after migration")
+		}
+
+		k, v := pathBucket.Cursor().First()
+		if k == nil {
+			t.Fatal("expected one migrated record in the path sub-bucket")
+		}
+		if !bytes.Equal(k, unixNanoKey(1234)) {
+			t.Fatalf("expected migrated record keyed by its own UnixNano, got key %v", k)
+		}
+		if !bytes.Equal(v, record) {
+			t.Fatal("migrated record's value should be unchanged")
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("verify migration: %v", err)
+	}
+}
+
+func TestSweepExpiredDropsOnlyOldSamples(t *testing.T) {
+	a := newTestAgent(t)
+	a.Retention = time.Hour
+
+	now := time.Now()
+	oldNano := now.Add(-2 * time.Hour).UnixNano()
+	freshNano := now.Add(-time.Minute).UnixNano()
+
+	err := a.Db.Update(func(tx *bolt.Tx) error {
+		pathBucket, err := a.dbBucket(tx).CreateBucketIfNotExists([]byte("/r/load"))
+		if err != nil {
+			return err
+		}
+
+		if err := pathBucket.Put(unixNanoKey(oldNano), []byte("old")); err != nil {
+			return err
+		}
+		return pathBucket.Put(unixNanoKey(freshNano), []byte("fresh"))
+	})
+	if err != nil {
+		t.Fatalf("seed samples: %v", err)
+	}
+
+	if err := a.sweepExpired(); err != nil {
+		t.Fatalf("sweepExpired: %v", err)
+	}
+
+	err = a.Db.View(func(tx *bolt.Tx) error {
+		pathBucket := a.dbBucket(tx).Bucket([]byte("/r/load"))
+
+		if v := pathBucket.Get(unixNanoKey(oldNano)); v != nil {
+			t.Fatal("expected the expired sample to be swept")
+		}
+		if v := pathBucket.Get(unixNanoKey(freshNano)); v == nil {
+			t.Fatal("expected the fresh sample to survive the sweep")
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("verify sweep: %v", err)
+	}
+}
+
+func TestStatsHandlerServesJSON(t *testing.T) {
+	a := &Agent{}
+	a.setRuntimes()
+
+	req := httptest.NewRequest("GET", "/stats?filter=/r/load*", nil)
+	rec := httptest.NewRecorder()
+
+	a.StatsHandler(rec, req)
+
+	if rec.Code != 200 {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Fatalf("expected Content-Type application/json, got %q", ct)
+	}
+}
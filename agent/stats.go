@@ -0,0 +1,150 @@
+package agent
+
+import (
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"time"
+
+	resourced_config "github.com/resourced/resourced/config"
+)
+
+// StatsReporter exposes a point-in-time snapshot of reader/writer execution
+// health, similar to Nomad's AllocStatsReporter. It lets callers (health
+// checks, dashboards) get one aggregated view instead of scraping each
+// reader's raw JSON blob individually.
+type StatsReporter interface {
+	LatestStats(pathFilter string) (*AgentStats, error)
+}
+
+// AgentStats is the snapshot returned by Agent.LatestStats.
+type AgentStats struct {
+	UnixNano int64                   `json:"UnixNano"`
+	Readers  map[string]*ReaderStats `json:"Readers"`
+}
+
+// ReaderStats pairs a reader/writer's most recent captured output with its
+// execution metadata.
+type ReaderStats struct {
+	Data                interface{} `json:"Data"`
+	LastRunDuration     string      `json:"LastRunDuration"`
+	LastError           string      `json:"LastError,omitempty"`
+	ConsecutiveFailures int         `json:"ConsecutiveFailures"`
+	NextScheduledRun    time.Time   `json:"NextScheduledRun"`
+	SuccessCount        int64       `json:"SuccessCount"`
+	FailureCount        int64       `json:"FailureCount"`
+}
+
+// readerRuntime is the in-memory execution metadata for one reader/writer path.
+type readerRuntime struct {
+	lastRunDuration     time.Duration
+	lastError           error
+	consecutiveFailures int
+	nextScheduledRun    time.Time
+	successCount        int64
+	failureCount        int64
+}
+
+// setRuntimes initializes the map tracking per-path execution metadata.
+func (a *Agent) setRuntimes() {
+	a.runtimeLock = new(sync.RWMutex)
+	a.runtimes = make(map[string]*readerRuntime)
+}
+
+// recordRunResult updates the execution metadata for config.Path. It is
+// called from Run() right around the saveRun call so the bolt write and the
+// in-memory stats never drift apart.
+func (a *Agent) recordRunResult(config resourced_config.Config, duration time.Duration, runErr error) {
+	if config.Path == "" {
+		return
+	}
+
+	a.runtimeLock.Lock()
+	defer a.runtimeLock.Unlock()
+
+	rt, ok := a.runtimes[config.Path]
+	if !ok {
+		rt = &readerRuntime{}
+		a.runtimes[config.Path] = rt
+	}
+
+	rt.lastRunDuration = duration
+	rt.lastError = runErr
+
+	if runErr != nil {
+		rt.consecutiveFailures++
+		rt.failureCount++
+	} else {
+		rt.consecutiveFailures = 0
+		rt.successCount++
+	}
+
+	interval, err := time.ParseDuration(config.Interval)
+	if err != nil {
+		interval = time.Minute
+	}
+	rt.nextScheduledRun = time.Now().Add(interval)
+}
+
+// LatestStats returns the most recent reader/writer output, filtered by a
+// glob on reader path (e.g. "/r/load*"), along with each match's execution
+// metadata.
+func (a *Agent) LatestStats(pathFilter string) (*AgentStats, error) {
+	a.runtimeLock.RLock()
+	defer a.runtimeLock.RUnlock()
+
+	stats := &AgentStats{
+		UnixNano: time.Now().UnixNano(),
+		Readers:  make(map[string]*ReaderStats),
+	}
+
+	for path, rt := range a.runtimes {
+		if pathFilter != "" {
+			matched, err := filepath.Match(pathFilter, path)
+			if err != nil {
+				return nil, err
+			}
+			if !matched {
+				continue
+			}
+		}
+
+		var data interface{}
+		dataBytes, err := a.GetRunByPath(path)
+		if err == nil && dataBytes != nil {
+			json.Unmarshal(dataBytes, &data)
+		}
+
+		readerStats := &ReaderStats{
+			Data:                data,
+			LastRunDuration:     rt.lastRunDuration.String(),
+			ConsecutiveFailures: rt.consecutiveFailures,
+			NextScheduledRun:    rt.nextScheduledRun,
+			SuccessCount:        rt.successCount,
+			FailureCount:        rt.failureCount,
+		}
+		if rt.lastError != nil {
+			readerStats.LastError = rt.lastError.Error()
+		}
+
+		stats.Readers[path] = readerStats
+	}
+
+	return stats, nil
+}
+
+// StatsHandler serves LatestStats as JSON, filtered by the "filter" query
+// parameter (e.g. GET /stats?filter=/r/load*). Mounting this at the "/stats"
+// route on the agent's HTTP mux belongs to the http server package, which
+// isn't part of this series' touched files.
+func (a *Agent) StatsHandler(w http.ResponseWriter, r *http.Request) {
+	stats, err := a.LatestStats(r.URL.Query().Get("filter"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+}
@@ -0,0 +1,66 @@
+// Package bus provides a pluggable publish/subscribe layer so reader and
+// writer results can fan out to external systems in addition to the local
+// BoltDB store that Agent.saveRun writes to.
+package bus
+
+// EventBus lets Agent publish reader/writer results to external systems
+// (an in-process channel, NATS, an HTTP webhook, Kafka, ...). Implementations
+// must be safe for concurrent use.
+type EventBus interface {
+	// Publish sends payload to topic.
+	Publish(topic string, payload []byte) error
+
+	// Subscribe registers handler to be called with the payload of every
+	// message published to topic from now on.
+	Subscribe(topic string, handler func(payload []byte)) (Subscription, error)
+}
+
+// Subscription represents an active Subscribe call. Unsubscribe stops delivery.
+type Subscription interface {
+	Unsubscribe() error
+}
+
+// Async wraps inner so Publish queues its work for a background goroutine
+// and returns immediately, instead of blocking the caller for as long as
+// inner.Publish takes. Use it for buses whose Publish can be slow or
+// unreliable (e.g. HTTPBus retrying a down webhook for tens of seconds) so a
+// stalled subscriber can't stall the reader/writer tick that called Publish.
+// Queued publishes are delivered in order; if the queue is full, the publish
+// is dropped rather than blocking.
+func Async(inner EventBus) EventBus {
+	b := &asyncBus{inner: inner, jobs: make(chan publishJob, 100)}
+	go b.run()
+	return b
+}
+
+type publishJob struct {
+	topic   string
+	payload []byte
+}
+
+type asyncBus struct {
+	inner EventBus
+	jobs  chan publishJob
+}
+
+func (b *asyncBus) run() {
+	for job := range b.jobs {
+		b.inner.Publish(job.topic, job.payload)
+	}
+}
+
+// Publish enqueues payload for the background worker and returns
+// immediately. A full queue drops the publish rather than blocking.
+func (b *asyncBus) Publish(topic string, payload []byte) error {
+	select {
+	case b.jobs <- publishJob{topic: topic, payload: payload}:
+	default:
+	}
+
+	return nil
+}
+
+// Subscribe delegates directly to inner; only Publish needs to be async.
+func (b *asyncBus) Subscribe(topic string, handler func(payload []byte)) (Subscription, error) {
+	return b.inner.Subscribe(topic, handler)
+}
@@ -0,0 +1,162 @@
+package bus
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestChannelBusPublishDeliversToSubscriber(t *testing.T) {
+	b := NewChannelBus()
+
+	var mu sync.Mutex
+	var received []byte
+
+	_, err := b.Subscribe("topic", func(payload []byte) {
+		mu.Lock()
+		received = payload
+		mu.Unlock()
+	})
+	if err != nil {
+		t.Fatalf("Subscribe returned error: %v", err)
+	}
+
+	if err := b.Publish("topic", []byte("hello")); err != nil {
+		t.Fatalf("Publish returned error: %v", err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		mu.Lock()
+		got := received
+		mu.Unlock()
+
+		if string(got) == "hello" {
+			return
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	t.Fatal("subscriber never received the published payload")
+}
+
+func TestChannelBusUnsubscribeStopsDelivery(t *testing.T) {
+	b := NewChannelBus()
+
+	var mu sync.Mutex
+	count := 0
+
+	sub, err := b.Subscribe("topic", func(payload []byte) {
+		mu.Lock()
+		count++
+		mu.Unlock()
+	})
+	if err != nil {
+		t.Fatalf("Subscribe returned error: %v", err)
+	}
+
+	if err := sub.Unsubscribe(); err != nil {
+		t.Fatalf("Unsubscribe returned error: %v", err)
+	}
+
+	if err := b.Publish("topic", []byte("should not arrive")); err != nil {
+		t.Fatalf("Publish returned error: %v", err)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+
+	mu.Lock()
+	got := count
+	mu.Unlock()
+
+	if got != 0 {
+		t.Fatalf("expected no deliveries after Unsubscribe, got %d", got)
+	}
+}
+
+func TestChannelBusUnsubscribeLeavesOtherSubscribersIntact(t *testing.T) {
+	b := NewChannelBus()
+
+	var mu sync.Mutex
+	var secondReceived bool
+
+	firstSub, err := b.Subscribe("topic", func(payload []byte) {})
+	if err != nil {
+		t.Fatalf("Subscribe returned error: %v", err)
+	}
+
+	_, err = b.Subscribe("topic", func(payload []byte) {
+		mu.Lock()
+		secondReceived = true
+		mu.Unlock()
+	})
+	if err != nil {
+		t.Fatalf("Subscribe returned error: %v", err)
+	}
+
+	if err := firstSub.Unsubscribe(); err != nil {
+		t.Fatalf("Unsubscribe returned error: %v", err)
+	}
+
+	if err := b.Publish("topic", []byte("hi")); err != nil {
+		t.Fatalf("Publish returned error: %v", err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		mu.Lock()
+		got := secondReceived
+		mu.Unlock()
+
+		if got {
+			return
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	t.Fatal("second subscriber should still receive messages after the first unsubscribed")
+}
+
+// blockingBus.Publish blocks until release is closed, simulating a slow
+// webhook so tests can assert Async.Publish doesn't wait for it.
+type blockingBus struct {
+	release chan struct{}
+	called  chan struct{}
+}
+
+func (b *blockingBus) Publish(topic string, payload []byte) error {
+	close(b.called)
+	<-b.release
+	return nil
+}
+
+func (b *blockingBus) Subscribe(topic string, handler func(payload []byte)) (Subscription, error) {
+	return nil, nil
+}
+
+func TestAsyncPublishDoesNotBlockCaller(t *testing.T) {
+	inner := &blockingBus{release: make(chan struct{}), called: make(chan struct{})}
+	async := Async(inner)
+
+	done := make(chan struct{})
+	go func() {
+		async.Publish("topic", []byte("payload"))
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Async.Publish blocked on the slow inner bus")
+	}
+
+	select {
+	case <-inner.called:
+	case <-time.After(time.Second):
+		t.Fatal("inner bus never received the queued publish")
+	}
+
+	close(inner.release)
+}
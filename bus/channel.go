@@ -0,0 +1,77 @@
+package bus
+
+import "sync"
+
+// NewChannelBus returns an in-process EventBus backed by Go channels. It
+// never leaves the process, so it's the cheapest bus to enable and a sane
+// default when no external broker is configured.
+func NewChannelBus() *ChannelBus {
+	return &ChannelBus{
+		subscribers: make(map[string][]chan []byte),
+	}
+}
+
+// ChannelBus fans out Publish calls to Subscribe'd channels within the
+// current process.
+type ChannelBus struct {
+	mu          sync.RWMutex
+	subscribers map[string][]chan []byte
+}
+
+// Publish sends payload to every channel currently subscribed to topic. A
+// subscriber that isn't keeping up has its message dropped rather than
+// blocking the publisher.
+func (b *ChannelBus) Publish(topic string, payload []byte) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, ch := range b.subscribers[topic] {
+		select {
+		case ch <- payload:
+		default:
+		}
+	}
+
+	return nil
+}
+
+// Subscribe registers handler to run in its own goroutine for every message
+// published to topic.
+func (b *ChannelBus) Subscribe(topic string, handler func(payload []byte)) (Subscription, error) {
+	ch := make(chan []byte, 100)
+
+	b.mu.Lock()
+	b.subscribers[topic] = append(b.subscribers[topic], ch)
+	b.mu.Unlock()
+
+	go func() {
+		for payload := range ch {
+			handler(payload)
+		}
+	}()
+
+	return &channelSubscription{bus: b, topic: topic, ch: ch}, nil
+}
+
+type channelSubscription struct {
+	bus   *ChannelBus
+	topic string
+	ch    chan []byte
+}
+
+// Unsubscribe removes the channel from its topic and stops its handler goroutine.
+func (s *channelSubscription) Unsubscribe() error {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+
+	chans := s.bus.subscribers[s.topic]
+	for i, ch := range chans {
+		if ch == s.ch {
+			s.bus.subscribers[s.topic] = append(chans[:i], chans[i+1:]...)
+			close(ch)
+			break
+		}
+	}
+
+	return nil
+}
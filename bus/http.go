@@ -0,0 +1,64 @@
+package bus
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// NewHTTPBus returns an EventBus that POSTs JSON payloads to a webhook URL
+// per topic. urlsByTopic maps topic name to the destination URL to POST to.
+func NewHTTPBus(urlsByTopic map[string]string) *HTTPBus {
+	return &HTTPBus{
+		urlsByTopic: urlsByTopic,
+		client:      &http.Client{Timeout: 10 * time.Second},
+		maxRetries:  3,
+	}
+}
+
+// HTTPBus publishes by POSTing JSON to a configured webhook URL, retrying
+// with a linear backoff on failure. It has no way to push data back to a
+// caller, so Subscribe is unsupported.
+type HTTPBus struct {
+	urlsByTopic map[string]string
+	client      *http.Client
+	maxRetries  int
+}
+
+// Publish POSTs payload to the URL configured for topic. Topics with no
+// configured URL are silently ignored so operators can wire up a subset.
+func (b *HTTPBus) Publish(topic string, payload []byte) error {
+	url, ok := b.urlsByTopic[topic]
+	if !ok {
+		return nil
+	}
+
+	var lastErr error
+
+	for attempt := 0; attempt <= b.maxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(time.Duration(attempt) * time.Second)
+		}
+
+		resp, err := b.client.Post(url, "application/json", bytes.NewReader(payload))
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			return nil
+		}
+
+		lastErr = fmt.Errorf("resourced/bus: webhook %s returned status %d", url, resp.StatusCode)
+	}
+
+	return lastErr
+}
+
+// Subscribe is unsupported: HTTPBus is publish-only.
+func (b *HTTPBus) Subscribe(topic string, handler func(payload []byte)) (Subscription, error) {
+	return nil, fmt.Errorf("resourced/bus: HTTPBus does not support Subscribe")
+}
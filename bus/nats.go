@@ -0,0 +1,47 @@
+package bus
+
+import (
+	"github.com/nats-io/nats"
+)
+
+// NewNatsBus connects to the NATS server at url and returns an EventBus
+// backed by that connection.
+func NewNatsBus(url string) (*NatsBus, error) {
+	conn, err := nats.Connect(url)
+	if err != nil {
+		return nil, err
+	}
+
+	return &NatsBus{conn: conn}, nil
+}
+
+// NatsBus publishes and subscribes through a NATS connection.
+type NatsBus struct {
+	conn *nats.Conn
+}
+
+// Publish sends payload as a NATS message on topic.
+func (b *NatsBus) Publish(topic string, payload []byte) error {
+	return b.conn.Publish(topic, payload)
+}
+
+// Subscribe registers handler to run for every NATS message delivered on topic.
+func (b *NatsBus) Subscribe(topic string, handler func(payload []byte)) (Subscription, error) {
+	sub, err := b.conn.Subscribe(topic, func(msg *nats.Msg) {
+		handler(msg.Data)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &natsSubscription{sub: sub}, nil
+}
+
+type natsSubscription struct {
+	sub *nats.Subscription
+}
+
+// Unsubscribe cancels the underlying NATS subscription.
+func (s *natsSubscription) Unsubscribe() error {
+	return s.sub.Unsubscribe()
+}
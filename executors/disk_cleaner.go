@@ -4,9 +4,11 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/resourced/resourced/libstring"
+	"github.com/resourced/resourced/libtime"
 )
 
 func init() {
@@ -24,6 +26,19 @@ type DiskCleaner struct {
 	Base
 	Data  map[string]interface{}
 	Globs []interface{}
+
+	// DryRun, when true, records what would be deleted in Data["WouldDelete"]
+	// instead of actually removing anything.
+	DryRun bool
+
+	// MinAge, e.g. "7d", skips any file whose mtime is newer than now minus
+	// this duration. Empty means no age filter.
+	MinAge string
+
+	// MaxBytes caps how many bytes a single run is allowed to free. A match
+	// that would push cumulative freed bytes past it is skipped; later, smaller
+	// matches that still fit are still considered. Zero means no budget.
+	MaxBytes int64
 }
 
 // Run shells out external program and store the output on c.Data.
@@ -31,8 +46,25 @@ func (dc *DiskCleaner) Run() error {
 	dc.Data["Conditions"] = dc.Conditions
 
 	if dc.IsConditionMet() && dc.LowThresholdExceeded() && !dc.HighThresholdExceeded() {
+		var minAge time.Duration
+		if dc.MinAge != "" {
+			parsedAge, err := libtime.ParseDuration(dc.MinAge)
+			if err != nil {
+				dc.Data["Error"] = err.Error()
+				dc.Data["ExitStatus"] = 1
+
+				return err
+			}
+			minAge = parsedAge
+		}
+
+		cutoff := time.Now().Add(-minAge)
+
 		successOutput := make([]string, 0)
 		failOutput := make([]string, 0)
+		wouldDeleteOutput := make([]string, 0)
+
+		var freedBytes int64
 
 		for _, globInterface := range dc.Globs {
 			glob := globInterface.(string)
@@ -47,11 +79,44 @@ func (dc *DiskCleaner) Run() error {
 			}
 
 			for _, fullpath := range matches {
-				err := os.RemoveAll(fullpath)
+				info, err := os.Stat(fullpath)
+				if err != nil {
+					continue
+				}
+
+				if dc.MinAge != "" && info.ModTime().After(cutoff) {
+					continue
+				}
+
+				if dc.MaxBytes > 0 && freedBytes+info.Size() > dc.MaxBytes {
+					logrus.WithFields(logrus.Fields{
+						"Path":       fullpath,
+						"Size":       info.Size(),
+						"FreedBytes": freedBytes,
+						"MaxBytes":   dc.MaxBytes,
+					}).Info("Skipping delete: would exceed MaxBytes budget")
+					continue
+				}
+
+				logrus.WithFields(logrus.Fields{
+					"Path":   fullpath,
+					"Size":   info.Size(),
+					"Age":    time.Since(info.ModTime()).String(),
+					"DryRun": dc.DryRun,
+				}).Info("Deleting file")
+
+				if dc.DryRun {
+					wouldDeleteOutput = append(wouldDeleteOutput, fullpath)
+					freedBytes += info.Size()
+					continue
+				}
+
+				err = os.RemoveAll(fullpath)
 				if err != nil {
 					failOutput = append(failOutput, fullpath)
 				} else {
 					successOutput = append(successOutput, fullpath)
+					freedBytes += info.Size()
 				}
 			}
 		}
@@ -64,12 +129,19 @@ func (dc *DiskCleaner) Run() error {
 
 		dc.Data["Success"] = successOutput
 		dc.Data["Failure"] = failOutput
+		dc.Data["EstimatedFreedBytes"] = freedBytes
+
+		if dc.DryRun {
+			dc.Data["WouldDelete"] = wouldDeleteOutput
+		}
 
-		if len(failOutput) > 0 || len(successOutput) > 0 {
+		if len(failOutput) > 0 || len(successOutput) > 0 || len(wouldDeleteOutput) > 0 {
 			logrus.WithFields(logrus.Fields{
-				"Success":    successOutput,
-				"Failure":    failOutput,
-				"ExitStatus": dc.Data["ExitStatus"],
+				"Success":             successOutput,
+				"Failure":             failOutput,
+				"WouldDelete":         wouldDeleteOutput,
+				"ExitStatus":          dc.Data["ExitStatus"],
+				"EstimatedFreedBytes": freedBytes,
 			}).Info("Deleted files")
 		}
 	}
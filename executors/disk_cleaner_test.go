@@ -0,0 +1,125 @@
+package executors
+
+// These tests assume a zero-value Base (no Conditions, no thresholds
+// configured) makes IsConditionMet/LowThresholdExceeded/HighThresholdExceeded
+// evaluate as "met", since that's the only way DiskCleaner.Run reaches its
+// delete logic without a condition/threshold config.
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestDiskCleaner(t *testing.T, dir string) *DiskCleaner {
+	t.Helper()
+
+	dc := NewDiskCleaner().(*DiskCleaner)
+	dc.Globs = []interface{}{filepath.Join(dir, "*")}
+
+	return dc
+}
+
+func writeFileWithAge(t *testing.T, dir, name string, size int, age time.Duration) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+
+	if err := os.WriteFile(path, make([]byte, size), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	mtime := time.Now().Add(-age)
+	if err := os.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatalf("Chtimes: %v", err)
+	}
+
+	return path
+}
+
+func TestDiskCleanerMinAgeExcludesFreshFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFileWithAge(t, dir, "fresh", 10, time.Minute)
+	old := writeFileWithAge(t, dir, "old", 10, 2*time.Hour)
+
+	dc := newTestDiskCleaner(t, dir)
+	dc.MinAge = "1h"
+	dc.DryRun = true
+
+	if err := dc.Run(); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	wouldDelete, _ := dc.Data["WouldDelete"].([]string)
+	if len(wouldDelete) != 1 || wouldDelete[0] != old {
+		t.Fatalf("expected only the aged-out file to be selected, got %v", wouldDelete)
+	}
+}
+
+func TestDiskCleanerMaxBytesStopsButConsidersSmallerLaterMatches(t *testing.T) {
+	dir := t.TempDir()
+	a := writeFileWithAge(t, dir, "a", 100, time.Hour)
+	writeFileWithAge(t, dir, "b", 200, time.Hour)
+	c := writeFileWithAge(t, dir, "c", 50, time.Hour)
+
+	dc := newTestDiskCleaner(t, dir)
+	dc.MaxBytes = 150
+	dc.DryRun = true
+
+	if err := dc.Run(); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	wouldDelete, _ := dc.Data["WouldDelete"].([]string)
+
+	selected := make(map[string]bool)
+	for _, path := range wouldDelete {
+		selected[path] = true
+	}
+
+	if !selected[a] {
+		t.Errorf("expected %s (fits within budget) to be selected", a)
+	}
+	if !selected[c] {
+		t.Errorf("expected %s (smaller, considered after the over-budget match) to be selected", c)
+	}
+	if len(wouldDelete) != 2 {
+		t.Errorf("expected exactly 2 files selected, got %v", wouldDelete)
+	}
+
+	freedBytes, _ := dc.Data["EstimatedFreedBytes"].(int64)
+	if freedBytes != 150 {
+		t.Errorf("expected EstimatedFreedBytes to be 150, got %d", freedBytes)
+	}
+}
+
+func TestDiskCleanerDryRunDoesNotDelete(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFileWithAge(t, dir, "keep-me", 42, time.Hour)
+
+	dc := newTestDiskCleaner(t, dir)
+	dc.DryRun = true
+
+	if err := dc.Run(); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("expected file to still exist after a dry run, got: %v", err)
+	}
+
+	wouldDelete, _ := dc.Data["WouldDelete"].([]string)
+	if len(wouldDelete) != 1 || wouldDelete[0] != path {
+		t.Fatalf("expected WouldDelete to list %s, got %v", path, wouldDelete)
+	}
+
+	freedBytes, _ := dc.Data["EstimatedFreedBytes"].(int64)
+	if freedBytes != 42 {
+		t.Fatalf("expected EstimatedFreedBytes to be 42, got %d", freedBytes)
+	}
+
+	if _, ok := dc.Data["Success"].([]string); ok && len(dc.Data["Success"].([]string)) != 0 {
+		t.Fatalf("expected no files to be reported as actually deleted during a dry run")
+	}
+}
@@ -0,0 +1,10 @@
+package writers
+
+import "time"
+
+// shouldFlush reports whether a batch of batchLen messages should be sent
+// now, either because it reached batchSize or flushInterval has elapsed
+// since lastFlush, whichever comes first.
+func shouldFlush(batchLen, batchSize int, lastFlush time.Time, flushInterval time.Duration) bool {
+	return batchLen >= batchSize || time.Since(lastFlush) >= flushInterval
+}
@@ -0,0 +1,28 @@
+package writers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestShouldFlushOnBatchSize(t *testing.T) {
+	if !shouldFlush(10, 10, time.Now(), time.Hour) {
+		t.Fatal("expected shouldFlush to report true once batchLen reaches batchSize")
+	}
+
+	if shouldFlush(9, 10, time.Now(), time.Hour) {
+		t.Fatal("expected shouldFlush to report false before batchLen reaches batchSize")
+	}
+}
+
+func TestShouldFlushOnFlushInterval(t *testing.T) {
+	lastFlush := time.Now().Add(-2 * time.Second)
+
+	if !shouldFlush(1, 100, lastFlush, time.Second) {
+		t.Fatal("expected shouldFlush to report true once flushInterval has elapsed")
+	}
+
+	if shouldFlush(1, 100, time.Now(), time.Second) {
+		t.Fatal("expected shouldFlush to report false before flushInterval has elapsed")
+	}
+}
@@ -0,0 +1,149 @@
+package writers
+
+import (
+	"bytes"
+	"encoding/json"
+	"text/template"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/resourced/resourced/libstring"
+)
+
+func init() {
+	Register("KafkaWriter", NewKafkaWriter)
+}
+
+// NewKafkaWriter is KafkaWriter's constructor.
+func NewKafkaWriter() IWriter {
+	kw := &KafkaWriter{}
+	kw.ReadersData = make(map[string][]byte)
+	kw.BatchSize = 100
+	kw.FlushInterval = "10s"
+
+	return kw
+}
+
+// KafkaWriter publishes every reader's captured output as one Kafka message,
+// flushing whenever BatchSize or FlushInterval is reached, whichever comes
+// first. Topic supports {{.Host.Name}} and {{.Tags}} interpolation.
+type KafkaWriter struct {
+	Base
+	Data map[string]interface{}
+
+	Brokers      []string
+	Topic        string
+	Tags         []string
+	TLS          bool
+	SASLUsername string
+	SASLPassword string
+
+	BatchSize     int
+	FlushInterval string
+}
+
+// Run encodes each reader's data as a timestamped Kafka message and flushes
+// it in batches of BatchSize or every FlushInterval, whichever comes first.
+// The record timestamp is the reader's own UnixNano, not publish time, so
+// downstream consumers see when the sample was actually captured.
+func (kw *KafkaWriter) Run() error {
+	kw.Data = make(map[string]interface{})
+
+	flushInterval, err := time.ParseDuration(kw.FlushInterval)
+	if err != nil {
+		flushInterval = 10 * time.Second
+	}
+
+	config := sarama.NewConfig()
+	config.Producer.Return.Successes = true
+
+	if kw.TLS {
+		config.Net.TLS.Enable = true
+	}
+	if kw.SASLUsername != "" {
+		config.Net.SASL.Enable = true
+		config.Net.SASL.User = kw.SASLUsername
+		config.Net.SASL.Password = kw.SASLPassword
+	}
+
+	producer, err := sarama.NewSyncProducer(kw.Brokers, config)
+	if err != nil {
+		return err
+	}
+	defer producer.Close()
+
+	batch := make([]*sarama.ProducerMessage, 0, kw.BatchSize)
+	lastFlush := time.Now()
+	sent := 0
+
+	for path, dataInBytes := range kw.ReadersData {
+		var sample struct {
+			UnixNano int64 `json:"UnixNano"`
+		}
+		json.Unmarshal(dataInBytes, &sample)
+
+		batch = append(batch, &sarama.ProducerMessage{
+			Topic:     kw.renderTopic(path),
+			Value:     sarama.ByteEncoder(dataInBytes),
+			Timestamp: time.Unix(0, sample.UnixNano),
+		})
+
+		if shouldFlush(len(batch), kw.BatchSize, lastFlush, flushInterval) {
+			if err := producer.SendMessages(batch); err != nil {
+				kw.Data["Error"] = err.Error()
+				return err
+			}
+			sent += len(batch)
+			batch = batch[:0]
+			lastFlush = time.Now()
+		}
+	}
+
+	if len(batch) > 0 {
+		if err := producer.SendMessages(batch); err != nil {
+			kw.Data["Error"] = err.Error()
+			return err
+		}
+		sent += len(batch)
+	}
+
+	kw.Data["Sent"] = sent
+
+	return nil
+}
+
+// renderTopic interpolates {{.Host.Name}} and Tags into kw.Topic for path,
+// falling back to the literal Topic string if it isn't a template.
+func (kw *KafkaWriter) renderTopic(path string) string {
+	tmpl, err := template.New("topic").Parse(kw.Topic)
+	if err != nil {
+		return kw.Topic
+	}
+
+	data := struct {
+		Host struct{ Name string }
+		Tags []string
+		Path string
+	}{Path: path, Tags: kw.Tags}
+	data.Host.Name = libstring.Hostname()
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return kw.Topic
+	}
+
+	return buf.String()
+}
+
+// SetTags seeds kw.Tags with the agent-wide tags, e.g. from RESOURCED_TAGS,
+// unless the writer's own config already set Tags explicitly.
+func (kw *KafkaWriter) SetTags(tags []string) {
+	if len(kw.Tags) == 0 {
+		kw.Tags = tags
+	}
+}
+
+// ToJson serialize Data field to JSON.
+func (kw *KafkaWriter) ToJson() ([]byte, error) {
+	return json.Marshal(kw.Data)
+}
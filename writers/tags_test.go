@@ -0,0 +1,43 @@
+package writers
+
+import "testing"
+
+func TestKafkaWriterSetTagsSeedsWhenEmpty(t *testing.T) {
+	kw := NewKafkaWriter().(*KafkaWriter)
+	kw.SetTags([]string{"env:prod"})
+
+	if len(kw.Tags) != 1 || kw.Tags[0] != "env:prod" {
+		t.Fatalf("expected SetTags to seed Tags, got %v", kw.Tags)
+	}
+}
+
+func TestKafkaWriterSetTagsDoesNotOverrideExplicitConfig(t *testing.T) {
+	kw := NewKafkaWriter().(*KafkaWriter)
+	kw.Tags = []string{"role:db"}
+
+	kw.SetTags([]string{"env:prod"})
+
+	if len(kw.Tags) != 1 || kw.Tags[0] != "role:db" {
+		t.Fatalf("expected explicit writer Tags to win, got %v", kw.Tags)
+	}
+}
+
+func TestAMQPWriterSetTagsSeedsWhenEmpty(t *testing.T) {
+	aw := NewAMQPWriter().(*AMQPWriter)
+	aw.SetTags([]string{"env:prod"})
+
+	if len(aw.Tags) != 1 || aw.Tags[0] != "env:prod" {
+		t.Fatalf("expected SetTags to seed Tags, got %v", aw.Tags)
+	}
+}
+
+func TestAMQPWriterSetTagsDoesNotOverrideExplicitConfig(t *testing.T) {
+	aw := NewAMQPWriter().(*AMQPWriter)
+	aw.Tags = []string{"role:db"}
+
+	aw.SetTags([]string{"env:prod"})
+
+	if len(aw.Tags) != 1 || aw.Tags[0] != "role:db" {
+		t.Fatalf("expected explicit writer Tags to win, got %v", aw.Tags)
+	}
+}